@@ -1,11 +1,14 @@
 package metrics
 
 import (
+	"context"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/catalyzeio/cli/commands/services"
 	"github.com/catalyzeio/cli/config"
 	"github.com/catalyzeio/cli/lib/auth"
 	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/shutdown"
 	"github.com/catalyzeio/cli/models"
 	"github.com/jawher/mow.cli"
 )
@@ -33,6 +36,8 @@ var Cmd = models.Command{
 			cmd.Command(MemorySubCmd.Name, MemorySubCmd.ShortHelp, MemorySubCmd.CmdFunc(settings))
 			cmd.Command(NetworkInSubCmd.Name, NetworkInSubCmd.ShortHelp, NetworkInSubCmd.CmdFunc(settings))
 			cmd.Command(NetworkOutSubCmd.Name, NetworkOutSubCmd.ShortHelp, NetworkOutSubCmd.CmdFunc(settings))
+			cmd.Command(ServeSubCmd.Name, ServeSubCmd.ShortHelp, ServeSubCmd.CmdFunc(settings))
+			cmd.Command(PushSubCmd.Name, PushSubCmd.ShortHelp, PushSubCmd.CmdFunc(settings))
 		}
 	},
 }
@@ -57,21 +62,30 @@ var CPUSubCmd = models.Command{
 			json := subCmd.BoolOpt("json", false, "Output the data as json")
 			csv := subCmd.BoolOpt("csv", false, "Output the data as csv")
 			spark := subCmd.BoolOpt("spark", false, "Output the data using spark lines")
+			prom := subCmd.BoolOpt("prom", false, "Output the data in Prometheus exposition format")
 			stream := subCmd.BoolOpt("stream", false, "Repeat calls once per minute until this process is interrupted.")
 			mins := subCmd.IntOpt("m mins", 1, "How many minutes worth of metrics to retrieve.")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+				signedIn, err := auth.New(settings, prompts.New()).Signin()
+				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, CPU, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				ctx, cancel := shutdown.NotifyContext()
+				defer cancel()
+				shutdown.WatchAndCleanup(ctx, settings, signedIn)
+				if *prom {
+					err = CmdMetricsProm(ctx, *serviceName, CPU, *stream, *mins, New(settings))
+				} else {
+					err = CmdMetrics(ctx, *serviceName, CPU, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				}
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark)] [--stream] [-m]"
+			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark | --prom)] [--stream] [-m]"
 		}
 	},
 }
@@ -96,21 +110,30 @@ var MemorySubCmd = models.Command{
 			json := subCmd.BoolOpt("json", false, "Output the data as json")
 			csv := subCmd.BoolOpt("csv", false, "Output the data as csv")
 			spark := subCmd.BoolOpt("spark", false, "Output the data using spark lines")
+			prom := subCmd.BoolOpt("prom", false, "Output the data in Prometheus exposition format")
 			stream := subCmd.BoolOpt("stream", false, "Repeat calls once per minute until this process is interrupted.")
 			mins := subCmd.IntOpt("m mins", 1, "How many minutes worth of metrics to retrieve.")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+				signedIn, err := auth.New(settings, prompts.New()).Signin()
+				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, Memory, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				ctx, cancel := shutdown.NotifyContext()
+				defer cancel()
+				shutdown.WatchAndCleanup(ctx, settings, signedIn)
+				if *prom {
+					err = CmdMetricsProm(ctx, *serviceName, Memory, *stream, *mins, New(settings))
+				} else {
+					err = CmdMetrics(ctx, *serviceName, Memory, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				}
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark)] [--stream] [-m]"
+			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark | --prom)] [--stream] [-m]"
 		}
 	},
 }
@@ -134,21 +157,30 @@ var NetworkInSubCmd = models.Command{
 			json := subCmd.BoolOpt("json", false, "Output the data as json")
 			csv := subCmd.BoolOpt("csv", false, "Output the data as csv")
 			spark := subCmd.BoolOpt("spark", false, "Output the data using spark lines")
+			prom := subCmd.BoolOpt("prom", false, "Output the data in Prometheus exposition format")
 			stream := subCmd.BoolOpt("stream", false, "Repeat calls once per minute until this process is interrupted.")
 			mins := subCmd.IntOpt("m mins", 1, "How many minutes worth of metrics to retrieve.")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+				signedIn, err := auth.New(settings, prompts.New()).Signin()
+				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, NetworkIn, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				ctx, cancel := shutdown.NotifyContext()
+				defer cancel()
+				shutdown.WatchAndCleanup(ctx, settings, signedIn)
+				if *prom {
+					err = CmdMetricsProm(ctx, *serviceName, NetworkIn, *stream, *mins, New(settings))
+				} else {
+					err = CmdMetrics(ctx, *serviceName, NetworkIn, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				}
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark)] [--stream] [-m]"
+			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark | --prom)] [--stream] [-m]"
 		}
 	},
 }
@@ -173,29 +205,38 @@ var NetworkOutSubCmd = models.Command{
 			json := subCmd.BoolOpt("json", false, "Output the data as json")
 			csv := subCmd.BoolOpt("csv", false, "Output the data as csv")
 			spark := subCmd.BoolOpt("spark", false, "Output the data using spark lines")
+			prom := subCmd.BoolOpt("prom", false, "Output the data in Prometheus exposition format")
 			stream := subCmd.BoolOpt("stream", false, "Repeat calls once per minute until this process is interrupted.")
 			mins := subCmd.IntOpt("m mins", 1, "How many minutes worth of metrics to retrieve.")
 			subCmd.Action = func() {
-				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+				signedIn, err := auth.New(settings, prompts.New()).Signin()
+				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, NetworkOut, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				ctx, cancel := shutdown.NotifyContext()
+				defer cancel()
+				shutdown.WatchAndCleanup(ctx, settings, signedIn)
+				if *prom {
+					err = CmdMetricsProm(ctx, *serviceName, NetworkOut, *stream, *mins, New(settings))
+				} else {
+					err = CmdMetrics(ctx, *serviceName, NetworkOut, *json, *csv, *spark, *stream, *mins, New(settings), services.New(settings))
+				}
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark)] [--stream] [-m]"
+			subCmd.Spec = "[SERVICE_NAME] [(--json | --csv | --spark | --prom)] [--stream] [-m]"
 		}
 	},
 }
 
 // IMetrics
 type IMetrics interface {
-	RetrieveEnvironmentMetrics(mins int) (*[]models.Metrics, error)
-	RetrieveServiceMetrics(mins int, svcID string) (*models.Metrics, error)
+	RetrieveEnvironmentMetrics(ctx context.Context, mins int) (*[]models.Metrics, error)
+	RetrieveServiceMetrics(ctx context.Context, mins int, svcID string) (*models.Metrics, error)
 }
 
 // SMetrics is a concrete implementation of IMetrics
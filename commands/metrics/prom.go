@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/models"
+)
+
+// promTypeKey maps a MetricType to the string stored in models.Metrics.Type
+// for samples of that kind.
+func promTypeKey(metricType MetricType) string {
+	switch metricType {
+	case CPU:
+		return "cpu"
+	case Memory:
+		return "memory"
+	case NetworkIn:
+		return "networkIn"
+	case NetworkOut:
+		return "networkOut"
+	default:
+		return ""
+	}
+}
+
+// promMetricName maps a MetricType to the Prometheus metric name emitted
+// for it.
+func promMetricName(metricType MetricType) string {
+	switch metricType {
+	case CPU:
+		return "datica_cpu_percent"
+	case Memory:
+		return "datica_memory_bytes"
+	case NetworkIn:
+		return "datica_network_in_bytes"
+	case NetworkOut:
+		return "datica_network_out_bytes"
+	default:
+		return "datica_unknown"
+	}
+}
+
+// FormatProm renders the samples in ms matching metricType in Prometheus
+// text exposition format, labeling each sample with environment, service,
+// pod, and org so a scrape can be sliced along any of those dimensions. Only
+// the newest sample for each distinct label set is emitted, since Prometheus
+// rejects a scrape containing more than one sample for the same series.
+func FormatProm(metricType MetricType, ms *[]models.Metrics) string {
+	name := promMetricName(metricType)
+	typeKey := promTypeKey(metricType)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# TYPE %s gauge\n", name)
+	for _, m := range latestPerSeries(typeKey, ms) {
+		fmt.Fprintf(&sb, "%s{environment=%q,service=%q,pod=%q,org=%q} %v\n",
+			name, m.EnvironmentName, m.ServiceName, m.Pod, m.OrgID, m.Value)
+	}
+	return sb.String()
+}
+
+// latestPerSeries returns, in a deterministic order, the newest sample of
+// the given type for each distinct environment/service/pod/org label set in
+// ms. m.Time is RFC3339, so the lexicographically greatest value is also the
+// most recent.
+func latestPerSeries(typeKey string, ms *[]models.Metrics) []models.Metrics {
+	if ms == nil {
+		return nil
+	}
+	latest := map[string]models.Metrics{}
+	for _, m := range *ms {
+		if m.Type != typeKey {
+			continue
+		}
+		key := strings.Join([]string{m.EnvironmentName, m.ServiceName, m.Pod, m.OrgID}, "\x00")
+		if cur, ok := latest[key]; !ok || m.Time > cur.Time {
+			latest[key] = m
+		}
+	}
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]models.Metrics, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, latest[key])
+	}
+	return out
+}
+
+// FormatAllProm renders every metric family found in ms in Prometheus text
+// exposition format, used by "metrics serve" and "metrics push" which scrape
+// everything in one pass rather than one metric type at a time.
+func FormatAllProm(ms *[]models.Metrics) string {
+	var sb strings.Builder
+	for _, metricType := range []MetricType{CPU, Memory, NetworkIn, NetworkOut} {
+		sb.WriteString(FormatProm(metricType, ms))
+	}
+	return sb.String()
+}
+
+// CmdMetricsProm fetches mins minutes of environment metrics and prints
+// metricType in Prometheus text exposition format, filtered down to
+// serviceName if one is given. This is the --prom output path for the
+// "metrics cpu/memory/network-in/network-out" sub commands. If stream is
+// true, it repeats every scrapeInterval until ctx is canceled, the same
+// cadence CmdPush polls at, instead of printing once and returning.
+func CmdMetricsProm(ctx context.Context, serviceName string, metricType MetricType, stream bool, mins int, iMetrics IMetrics) error {
+	if err := promOnce(ctx, serviceName, metricType, mins, iMetrics); err != nil {
+		return err
+	}
+	if !stream {
+		return nil
+	}
+	ticker := time.NewTicker(scrapeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := promOnce(ctx, serviceName, metricType, mins, iMetrics); err != nil {
+				logrus.Printf("Error retrieving metrics: %s", err.Error())
+			}
+		}
+	}
+}
+
+func promOnce(ctx context.Context, serviceName string, metricType MetricType, mins int, iMetrics IMetrics) error {
+	ms, err := iMetrics.RetrieveEnvironmentMetrics(ctx, mins)
+	if err != nil {
+		return err
+	}
+	if serviceName != "" {
+		filtered := make([]models.Metrics, 0, len(*ms))
+		for _, m := range *ms {
+			if m.ServiceName == serviceName {
+				filtered = append(filtered, m)
+			}
+		}
+		ms = &filtered
+	}
+	fmt.Print(FormatProm(metricType, ms))
+	return nil
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/shutdown"
+	"github.com/catalyzeio/cli/models"
+	"github.com/jawher/mow.cli"
+)
+
+var PushSubCmd = models.Command{
+	Name:      "push",
+	ShortHelp: "Push CPU, memory, and network metrics to a Prometheus Pushgateway",
+	LongHelp: "`metrics push` polls your environment once a minute and POSTs the samples to a Pushgateway in Prometheus exposition format, " +
+		"for environments where Prometheus cannot reach a developer laptop directly to scrape it. " +
+		"Here are some sample commands\n\n" +
+		"```datica metrics push --gateway http://pushgateway.example.com:9091```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			gateway := subCmd.StringOpt("gateway", "", "The base URL of the Pushgateway to push samples to")
+			mins := subCmd.IntOpt("m mins", 1, "How many minutes worth of metrics to retrieve on each poll")
+			subCmd.Action = func() {
+				signedIn, err := auth.New(settings, prompts.New()).Signin()
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				ctx, cancel := shutdown.NotifyContext()
+				defer cancel()
+				shutdown.WatchAndCleanup(ctx, settings, signedIn)
+				err = CmdPush(ctx, *gateway, *mins, settings, New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "--gateway [-m]"
+		}
+	},
+}
+
+// CmdPush polls iMetrics every scrapeInterval and pushes the samples to
+// gateway in Prometheus exposition format until ctx is canceled.
+func CmdPush(ctx context.Context, gateway string, mins int, settings *models.Settings, iMetrics IMetrics) error {
+	url := fmt.Sprintf("%s/metrics/job/datica/instance/%s", strings.TrimRight(gateway, "/"), settings.EnvironmentName)
+	if err := pushOnce(ctx, url, mins, iMetrics); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(scrapeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := pushOnce(ctx, url, mins, iMetrics); err != nil {
+				logrus.Printf("Error pushing metrics to %s: %s", url, err.Error())
+			}
+		}
+	}
+}
+
+func pushOnce(ctx context.Context, url string, mins int, iMetrics IMetrics) error {
+	ms, err := iMetrics.RetrieveEnvironmentMetrics(ctx, mins)
+	if err != nil {
+		return err
+	}
+	text := FormatAllProm(ms)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(text))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Pushgateway at %s responded with status %d", url, resp.StatusCode)
+	}
+	return nil
+}
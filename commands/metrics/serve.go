@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/shutdown"
+	"github.com/catalyzeio/cli/models"
+	"github.com/jawher/mow.cli"
+)
+
+// scrapeInterval is how often "metrics serve" and "metrics push" refresh
+// their cached samples, matching the cadence of --stream on the other
+// metrics sub commands.
+const scrapeInterval = time.Minute
+
+var ServeSubCmd = models.Command{
+	Name:      "serve",
+	ShortHelp: "Expose CPU, memory, and network metrics over HTTP for Prometheus to scrape",
+	LongHelp: "`metrics serve` polls your environment once a minute and exposes the samples at `/metrics` in Prometheus exposition format, " +
+		"so an existing Prometheus server can scrape a developer laptop without a separate agent. " +
+		"Here are some sample commands\n\n" +
+		"```datica metrics serve\n" +
+		"datica metrics serve --addr :9100```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			addr := subCmd.StringOpt("addr", ":9100", "The address to listen on")
+			mins := subCmd.IntOpt("m mins", 1, "How many minutes worth of metrics to retrieve on each poll")
+			subCmd.Action = func() {
+				signedIn, err := auth.New(settings, prompts.New()).Signin()
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				ctx, cancel := shutdown.NotifyContext()
+				defer cancel()
+				shutdown.WatchAndCleanup(ctx, settings, signedIn)
+				err = CmdServe(ctx, *addr, *mins, New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "[--addr] [-m]"
+		}
+	},
+}
+
+// CmdServe polls iMetrics every scrapeInterval and serves the latest samples
+// at /metrics in Prometheus exposition format until ctx is canceled, at
+// which point it shuts the HTTP server down and returns.
+func CmdServe(ctx context.Context, addr string, mins int, iMetrics IMetrics) error {
+	cache := &promCache{}
+	if err := cache.refresh(ctx, iMetrics, mins); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cache.get()))
+	})
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		ticker := time.NewTicker(scrapeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				server.Shutdown(context.Background())
+				return
+			case <-ticker.C:
+				if err := cache.refresh(ctx, iMetrics, mins); err != nil {
+					logrus.Printf("Error refreshing metrics: %s", err.Error())
+				}
+			}
+		}
+	}()
+
+	logrus.Printf("Serving Prometheus metrics on %s/metrics", addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// promCache holds the most recently rendered Prometheus exposition text so
+// /metrics requests never block on an in-flight poll.
+type promCache struct {
+	mu   sync.RWMutex
+	text string
+}
+
+func (c *promCache) refresh(ctx context.Context, iMetrics IMetrics, mins int) error {
+	ms, err := iMetrics.RetrieveEnvironmentMetrics(ctx, mins)
+	if err != nil {
+		return err
+	}
+	text := FormatAllProm(ms)
+	c.mu.Lock()
+	c.text = text
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *promCache) get() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.text
+}
@@ -0,0 +1,50 @@
+package plugin
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/lib/plugins"
+	"github.com/catalyzeio/cli/models"
+	"github.com/jawher/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "plugin",
+	ShortHelp: "Manage CLI plugins",
+	LongHelp: "The `plugin` command lets you discover executables on $PATH named `datica-<name>`, " +
+		"each of which becomes available as the subcommand `datica <name>` with the current settings injected as `DATICA_*` env vars. " +
+		"The `plugin` command cannot be run directly but has sub commands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.Command(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var ListSubCmd = models.Command{
+	Name:      "list",
+	ShortHelp: "List plugins discovered on $PATH",
+	LongHelp: "`plugin list` prints every `datica-<name>` executable found on $PATH along with its resolved path. " +
+		"Here are some sample commands\n\n" +
+		"```datica plugin list```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			subCmd.Action = func() {
+				CmdList()
+			}
+		}
+	},
+}
+
+// CmdList prints every plugin found on $PATH and where it was found.
+func CmdList() {
+	found := plugins.Discover()
+	if len(found) == 0 {
+		logrus.Println("No plugins found. Plugins are executables on $PATH named \"datica-<name>\".")
+		return
+	}
+	for _, name := range plugins.Names(found) {
+		logrus.Printf("%s\t%s", name, found[name])
+	}
+}
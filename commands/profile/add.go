@@ -0,0 +1,17 @@
+package profile
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/models"
+)
+
+// CmdAdd creates a new, empty profile named profileName.
+func CmdAdd(profileName string, settings *models.Settings) error {
+	err := config.AddProfile(profileName, settings)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Profile \"%s\" created. Run \"datica profile use %s\" to switch to it.", profileName, profileName)
+	return nil
+}
@@ -0,0 +1,104 @@
+package profile
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/models"
+	"github.com/jawher/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "profile",
+	ShortHelp: "Manage named CLI profiles",
+	LongHelp: "The `profile` command lets you manage multiple named sets of hosts, credentials, and environment associations, " +
+		"for example one profile per Datica account or environment tier. " +
+		"Switch between them with the global `--profile` flag or the `DATICA_PROFILE` env var. " +
+		"The `profile` command cannot be run directly but has sub commands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.Command(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.CmdFunc(settings))
+			cmd.Command(AddSubCmd.Name, AddSubCmd.ShortHelp, AddSubCmd.CmdFunc(settings))
+			cmd.Command(UseSubCmd.Name, UseSubCmd.ShortHelp, UseSubCmd.CmdFunc(settings))
+			cmd.Command(RemoveSubCmd.Name, RemoveSubCmd.ShortHelp, RemoveSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var ListSubCmd = models.Command{
+	Name:      "list",
+	ShortHelp: "List all profiles and show which one is active",
+	LongHelp: "`profile list` prints every profile found in your settings file and marks the currently active one. " +
+		"Here are some sample commands\n\n" +
+		"```datica profile list```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			subCmd.Action = func() {
+				if err := CmdList(settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+		}
+	},
+}
+
+var AddSubCmd = models.Command{
+	Name:      "add",
+	ShortHelp: "Create a new, empty profile",
+	LongHelp: "`profile add` creates a new profile with the given name. " +
+		"The new profile starts out with no hosts, credentials, or associated environments; " +
+		"run `datica associate` and sign in again after switching to it. " +
+		"Here are some sample commands\n\n" +
+		"```datica profile add staging```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			profileName := subCmd.StringArg("PROFILE_NAME", "", "The name of the profile to create")
+			subCmd.Action = func() {
+				if err := CmdAdd(*profileName, settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "PROFILE_NAME"
+		}
+	},
+}
+
+var UseSubCmd = models.Command{
+	Name:      "use",
+	ShortHelp: "Switch the active profile",
+	LongHelp: "`profile use` switches which profile is active for subsequent commands until overridden by `--profile` or `DATICA_PROFILE`. " +
+		"Here are some sample commands\n\n" +
+		"```datica profile use staging```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			profileName := subCmd.StringArg("PROFILE_NAME", "", "The name of the profile to switch to")
+			subCmd.Action = func() {
+				if err := CmdUse(*profileName, settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "PROFILE_NAME"
+		}
+	},
+}
+
+var RemoveSubCmd = models.Command{
+	Name:      "remove",
+	ShortHelp: "Remove a profile",
+	LongHelp: "`profile remove` deletes a profile and everything associated with it. " +
+		"The \"default\" profile and the currently active profile cannot be removed. " +
+		"Here are some sample commands\n\n" +
+		"```datica profile remove staging```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			profileName := subCmd.StringArg("PROFILE_NAME", "", "The name of the profile to remove")
+			subCmd.Action = func() {
+				if err := CmdRemove(*profileName, settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "PROFILE_NAME"
+		}
+	},
+}
@@ -0,0 +1,23 @@
+package profile
+
+import (
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/models"
+)
+
+// CmdList prints every known profile, marking the currently active one.
+func CmdList(settings *models.Settings) error {
+	names, current := config.ListProfiles(settings)
+	sort.Strings(names)
+	for _, name := range names {
+		if name == current {
+			logrus.Printf("* %s (active)", name)
+		} else {
+			logrus.Printf("  %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package profile
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/models"
+)
+
+// CmdRemove deletes the profile named profileName.
+func CmdRemove(profileName string, settings *models.Settings) error {
+	err := config.RemoveProfile(profileName, settings)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Profile \"%s\" removed", profileName)
+	return nil
+}
@@ -0,0 +1,17 @@
+package profile
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/models"
+)
+
+// CmdUse switches the active profile to profileName.
+func CmdUse(profileName string, settings *models.Settings) error {
+	err := config.UseProfile(profileName, settings)
+	if err != nil {
+		return err
+	}
+	logrus.Printf("Switched to profile \"%s\"", profileName)
+	return nil
+}
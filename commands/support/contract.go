@@ -0,0 +1,68 @@
+package support
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/metrics"
+	"github.com/catalyzeio/cli/commands/services"
+	"github.com/catalyzeio/cli/commands/status"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/lib/shutdown"
+	"github.com/catalyzeio/cli/models"
+	"github.com/jawher/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "support",
+	ShortHelp: "Gather diagnostic information to send to Datica support",
+	LongHelp: "The `support` command bundles up diagnostic information about your CLI and environment. " +
+		"This is useful when working with Datica support so they have everything they need without you hand-copying IDs or logs. " +
+		"The `support` command cannot be run directly but has sub commands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.Command(DumpSubCmd.Name, DumpSubCmd.ShortHelp, DumpSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var DumpSubCmd = models.Command{
+	Name:      "dump",
+	ShortHelp: "Create a redacted diagnostic bundle for the current association",
+	LongHelp: "`support dump` writes a `datica-support-<timestamp>.tar.gz` containing a redacted copy of your settings file, " +
+		"the CLI version, resolved hosts and their versions, `services list` and `status` output for the current association, " +
+		"and recent CPU/Memory/NetworkIn/NetworkOut metrics for every service. " +
+		"Passwords, session tokens, and usernames are scrubbed before anything is written to the archive. " +
+		"Use `--stdout` to stream the archive to stdout instead of writing a file, and `--no-metrics` to skip the metrics collection entirely. " +
+		"Here are some sample commands\n\n" +
+		"```datica support dump\n" +
+		"datica support dump --minutes 30\n" +
+		"datica support dump --no-metrics\n" +
+		"datica support dump --stdout > bundle.tar.gz```",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			stdout := subCmd.BoolOpt("stdout", false, "Stream the tarball to stdout instead of writing a file")
+			noMetrics := subCmd.BoolOpt("no-metrics", false, "Skip collecting service and environment metrics")
+			mins := subCmd.IntOpt("minutes", 15, "How many minutes worth of metrics to include")
+			subCmd.Action = func() {
+				signedIn, err := auth.New(settings, prompts.New()).Signin()
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(true, true, settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				ctx, cancel := shutdown.NotifyContext()
+				defer cancel()
+				shutdown.WatchAndCleanup(ctx, settings, signedIn)
+				err = CmdDump(ctx, *stdout, *noMetrics, *mins, settings, metrics.New(settings), services.New(settings), status.New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "[--stdout] [--no-metrics] [--minutes]"
+		}
+	},
+}
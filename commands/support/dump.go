@@ -0,0 +1,148 @@
+package support
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/commands/metrics"
+	"github.com/catalyzeio/cli/models"
+)
+
+const redacted = "REDACTED"
+
+// IServices is the subset of commands/services.IServices that support dump
+// needs in order to include `services list` output in the bundle.
+type IServices interface {
+	List() (*[]models.Service, error)
+}
+
+// IStatus is the subset of commands/status.IStatus that support dump needs
+// in order to include `status` output in the bundle.
+type IStatus interface {
+	Status() (*models.StatusReport, error)
+}
+
+// CmdDump gathers a redacted settings file, resolved hosts, services list,
+// status, and (unless noMetrics) the last mins minutes of metrics for every
+// service into a single datica-support-<timestamp>.tar.gz, either writing it
+// to the current directory or streaming it to stdout.
+func CmdDump(ctx context.Context, toStdout, noMetrics bool, mins int, settings *models.Settings, iMetrics metrics.IMetrics, iServices IServices, iStatus IStatus) error {
+	var buf []byte
+	var err error
+	if buf, err = buildBundle(ctx, noMetrics, mins, settings, iMetrics, iServices, iStatus); err != nil {
+		return err
+	}
+
+	if toStdout {
+		_, err = os.Stdout.Write(buf)
+		return err
+	}
+
+	name := fmt.Sprintf("datica-support-%d.tar.gz", time.Now().Unix())
+	if err = ioutil.WriteFile(name, buf, 0644); err != nil {
+		return err
+	}
+	logrus.Printf("Wrote %s", name)
+	return nil
+}
+
+// buildBundle assembles the tar.gz archive in memory and returns its bytes.
+func buildBundle(ctx context.Context, noMetrics bool, mins int, settings *models.Settings, iMetrics metrics.IMetrics, iServices IServices, iStatus IStatus) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+
+	if err := addJSONFile(tw, "version.json", map[string]string{
+		"cliVersion":      settings.Version,
+		"accountsHost":    settings.AccountsHost,
+		"authHost":        settings.AuthHost,
+		"authHostVersion": settings.AuthHostVersion,
+		"paasHost":        settings.PaasHost,
+		"paasHostVersion": settings.PaasHostVersion,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := addJSONFile(tw, "settings.json", redactSettings(settings)); err != nil {
+		return nil, err
+	}
+
+	svcs, err := iServices.List()
+	if err != nil {
+		logrus.Debugf("Error retrieving services list for support dump: %s", err.Error())
+	} else if err = addJSONFile(tw, "services.json", svcs); err != nil {
+		return nil, err
+	}
+
+	statusReport, err := iStatus.Status()
+	if err != nil {
+		logrus.Debugf("Error retrieving status for support dump: %s", err.Error())
+	} else if err = addJSONFile(tw, "status.json", statusReport); err != nil {
+		return nil, err
+	}
+
+	if !noMetrics {
+		envMetrics, err := iMetrics.RetrieveEnvironmentMetrics(ctx, mins)
+		if err != nil {
+			logrus.Debugf("Error retrieving metrics for support dump: %s", err.Error())
+		} else if err = addJSONFile(tw, "metrics.json", envMetrics); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addJSONFile marshals v as indented JSON and writes it to tw as a single
+// named entry.
+func addJSONFile(tw *tar.Writer, name string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0600,
+		Size: int64(len(b)),
+	}); err != nil {
+		return err
+	}
+	_, err = tw.Write(b)
+	return err
+}
+
+// redactSettings returns a copy of settings with every credential scrubbed
+// so the bundle is safe to attach to a support ticket. Username, Password,
+// and SessionToken already live outside the settings file in a
+// CredentialStore; this also covers LegacyUsername/LegacySessionToken,
+// which are only non-empty for the instant between reading an
+// unmigrated settings file and config.migrateCredentials clearing them.
+func redactSettings(settings *models.Settings) *models.Settings {
+	cp := *settings
+	cp.Password = redacted
+	cp.SessionToken = redacted
+	cp.Username = redacted
+	cp.UsersID = redacted
+	cp.LegacyUsername = redacted
+	cp.LegacySessionToken = redacted
+	cp.Profiles = make(map[string]models.Profile, len(settings.Profiles))
+	for name, profile := range settings.Profiles {
+		profile.LegacyUsername = redacted
+		cp.Profiles[name] = profile
+	}
+	return &cp
+}
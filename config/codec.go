@@ -0,0 +1,41 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/catalyzeio/cli/models"
+	"gopkg.in/yaml.v3"
+)
+
+// isYAMLPath reports whether path should be encoded/decoded as YAML based on
+// its file extension. Anything else, including the extensionless .datica
+// file written by older CLI versions, is treated as JSON for backward
+// compatibility.
+func isYAMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// marshalSettings encodes settings using the codec selected by path's
+// extension.
+func marshalSettings(path string, settings *models.Settings) ([]byte, error) {
+	if isYAMLPath(path) {
+		return yaml.Marshal(settings)
+	}
+	return json.MarshalIndent(settings, "", "  ")
+}
+
+// unmarshalSettings decodes data into settings using the codec selected by
+// path's extension.
+func unmarshalSettings(path string, data []byte, settings *models.Settings) error {
+	if isYAMLPath(path) {
+		return yaml.Unmarshal(data, settings)
+	}
+	return json.Unmarshal(data, settings)
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Credentials are the secrets associated with a single profile: the
+// username used to sign in, plus the session token issued by a successful
+// sign in. A CredentialStore persists these outside of the settings file;
+// the file itself only ever holds a CredentialRef pointing at them. The
+// password itself is never persisted anywhere; it only ever lives in
+// memory for the duration of the sign in that exchanges it for a session
+// token.
+type Credentials struct {
+	Username     string
+	SessionToken string
+}
+
+// ErrCredentialNotFound is returned by a CredentialStore when ref has no
+// stored credentials, e.g. the first time a profile is used.
+var ErrCredentialNotFound = errors.New("no credentials found for that reference")
+
+// CredentialStore persists Credentials outside of the settings file, keyed
+// by an opaque reference such as "datica/production". Implementations are
+// chosen per OS by NewCredentialStore: macOS Keychain, Windows Credential
+// Manager, libsecret on Linux, and an encrypted file as a last resort
+// everywhere else or when the native store is unavailable.
+type CredentialStore interface {
+	Get(ref string) (*Credentials, error)
+	Set(ref string, creds *Credentials) error
+	Delete(ref string) error
+}
+
+// credentialRef returns the CredentialStore key for a named profile.
+func credentialRef(profileName string) string {
+	return "datica/" + profileName
+}
+
+// marshalCredentials and unmarshalCredentials are shared by the native
+// CredentialStore implementations, each of which stores Credentials as an
+// opaque blob alongside ref in its platform's secret store.
+func marshalCredentials(creds *Credentials) ([]byte, error) {
+	return json.Marshal(creds)
+}
+
+func unmarshalCredentials(data []byte, creds *Credentials) error {
+	return json.Unmarshal(data, creds)
+}
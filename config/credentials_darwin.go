@@ -0,0 +1,76 @@
+package config
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/keybase/go-keychain"
+)
+
+const keychainService = "datica"
+
+type keychainCredentialStore struct {
+	fallback *fileCredentialStore
+}
+
+// NewCredentialStore returns a CredentialStore backed by the macOS
+// Keychain, falling back to an encrypted file if the Keychain cannot be
+// reached (e.g. running headless over SSH without a login session).
+func NewCredentialStore() CredentialStore {
+	fallback, err := newFileCredentialStore()
+	if err != nil {
+		logrus.Debugf("Error preparing fallback credential store: %s", err.Error())
+	}
+	return &keychainCredentialStore{fallback: fallback}
+}
+
+func (s *keychainCredentialStore) Get(ref string) (*Credentials, error) {
+	item := keychain.NewItem()
+	item.SetSecClass(keychain.SecClassGenericPassword)
+	item.SetService(keychainService)
+	item.SetAccount(ref)
+	item.SetMatchLimit(keychain.MatchLimitOne)
+	item.SetReturnData(true)
+
+	results, err := keychain.QueryItem(item)
+	if err != nil {
+		if s.fallback == nil {
+			return nil, err
+		}
+		return s.fallback.Get(ref)
+	}
+	if len(results) == 0 {
+		return nil, ErrCredentialNotFound
+	}
+	var creds Credentials
+	if err = unmarshalCredentials(results[0].Data, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (s *keychainCredentialStore) Set(ref string, creds *Credentials) error {
+	data, err := marshalCredentials(creds)
+	if err != nil {
+		return err
+	}
+
+	keychain.DeleteGenericPasswordItem(keychainService, ref)
+	item := keychain.NewGenericPassword(keychainService, ref, "", data, "")
+	item.SetSynchronizable(keychain.SynchronizableNo)
+	if err = keychain.AddItem(item); err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Set(ref, creds)
+	}
+	return nil
+}
+
+func (s *keychainCredentialStore) Delete(ref string) error {
+	if err := keychain.DeleteGenericPasswordItem(keychainService, ref); err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Delete(ref)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// FallbackCredentialsFile is where credentials are kept, encrypted, when no
+// native OS credential store is available.
+const FallbackCredentialsFile = ".datica-credentials"
+
+// PassphraseEnvVar lets scripts and CI supply the fallback credential
+// store's passphrase without an interactive prompt.
+const PassphraseEnvVar = "DATICA_PASSPHRASE"
+
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// fileCredentialStore is a CredentialStore backed by a single encrypted
+// file. Every ref's Credentials are kept in one JSON blob, encrypted as a
+// whole with NaCl secretbox using a key derived via scrypt from a
+// passphrase the user supplies (DATICA_PASSPHRASE, or an interactive
+// prompt) and which is never written to disk. The scrypt salt and the
+// secretbox nonce are not secret and are stored alongside the ciphertext
+// in the same file, so the key can be re-derived from the passphrase on
+// the next read.
+type fileCredentialStore struct {
+	path       string
+	passphrase []byte
+}
+
+type fallbackRecord struct {
+	Salt       [32]byte `json:"salt"`
+	Nonce      [24]byte `json:"nonce"`
+	Ciphertext []byte   `json:"ciphertext"`
+}
+
+// newFileCredentialStore returns the encrypted-file CredentialStore used
+// when no native credential store is available on this platform, or when
+// one is available but returns an error (e.g. no Keychain in a headless
+// CI environment).
+func newFileCredentialStore() (*fileCredentialStore, error) {
+	homeDir, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := resolvePassphrase()
+	if err != nil {
+		return nil, err
+	}
+	return &fileCredentialStore{
+		path:       filepath.Join(homeDir, FallbackCredentialsFile),
+		passphrase: passphrase,
+	}, nil
+}
+
+// resolvePassphrase returns the passphrase used to derive the fallback
+// store's encryption key, from DATICA_PASSPHRASE if set, otherwise by
+// prompting for it interactively. It is never persisted.
+func resolvePassphrase() ([]byte, error) {
+	if passphrase := os.Getenv(PassphraseEnvVar); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	fmt.Print("Enter a passphrase to protect your local credential store: ")
+	var passphrase string
+	if _, err := fmt.Scanln(&passphrase); err != nil {
+		return nil, err
+	}
+	return []byte(passphrase), nil
+}
+
+func (s *fileCredentialStore) Get(ref string) (*Credentials, error) {
+	all, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := all[ref]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	return &creds, nil
+}
+
+func (s *fileCredentialStore) Set(ref string, creds *Credentials) error {
+	lock := flock.New(s.path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	all, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	all[ref] = *creds
+	return s.writeAll(all)
+}
+
+func (s *fileCredentialStore) Delete(ref string) error {
+	lock := flock.New(s.path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	all, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	delete(all, ref)
+	return s.writeAll(all)
+}
+
+func (s *fileCredentialStore) readAll() (map[string]Credentials, error) {
+	all := map[string]Credentials{}
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return all, nil
+		}
+		return nil, err
+	}
+
+	var rec fallbackRecord
+	if err = json.Unmarshal(b, &rec); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key(s.passphrase, rec.Salt[:], scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	var keyArr [32]byte
+	copy(keyArr[:], key)
+
+	plaintext, ok := secretbox.Open(nil, rec.Ciphertext, &rec.Nonce, &keyArr)
+	if !ok {
+		return nil, fmt.Errorf("could not decrypt %s: wrong key or corrupt file", s.path)
+	}
+	if err = json.Unmarshal(plaintext, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (s *fileCredentialStore) writeAll(all map[string]Credentials) error {
+	plaintext, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	var rec fallbackRecord
+	if _, err = rand.Read(rec.Salt[:]); err != nil {
+		return err
+	}
+	if _, err = rand.Read(rec.Nonce[:]); err != nil {
+		return err
+	}
+	key, err := scrypt.Key(s.passphrase, rec.Salt[:], scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return err
+	}
+	var keyArr [32]byte
+	copy(keyArr[:], key)
+	rec.Ciphertext = secretbox.Seal(nil, plaintext, &rec.Nonce, &keyArr)
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, b, 0600)
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/gsterjov/go-libsecret"
+)
+
+const libsecretLabelPrefix = "datica: "
+
+type libsecretCredentialStore struct {
+	fallback *fileCredentialStore
+}
+
+// NewCredentialStore returns a CredentialStore backed by libsecret (the
+// Secret Service used by GNOME Keyring, KWallet, and similar), falling
+// back to an encrypted file if no Secret Service is running (e.g. a
+// headless server with no session bus).
+func NewCredentialStore() CredentialStore {
+	fallback, err := newFileCredentialStore()
+	if err != nil {
+		logrus.Debugf("Error preparing fallback credential store: %s", err.Error())
+	}
+	return &libsecretCredentialStore{fallback: fallback}
+}
+
+func (s *libsecretCredentialStore) collection() (*libsecret.Collection, error) {
+	service, err := libsecret.NewService()
+	if err != nil {
+		return nil, err
+	}
+	return service.GetCollection("login")
+}
+
+func (s *libsecretCredentialStore) Get(ref string) (*Credentials, error) {
+	collection, err := s.collection()
+	if err != nil {
+		if s.fallback == nil {
+			return nil, err
+		}
+		return s.fallback.Get(ref)
+	}
+	items, err := collection.SearchItems(map[string]string{"ref": ref})
+	if err != nil || len(items) == 0 {
+		if s.fallback == nil {
+			return nil, ErrCredentialNotFound
+		}
+		return s.fallback.Get(ref)
+	}
+	secret, err := items[0].GetSecret()
+	if err != nil {
+		return nil, err
+	}
+	var creds Credentials
+	if err = unmarshalCredentials(secret.Value, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (s *libsecretCredentialStore) Set(ref string, creds *Credentials) error {
+	collection, err := s.collection()
+	if err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Set(ref, creds)
+	}
+	data, err := marshalCredentials(creds)
+	if err != nil {
+		return err
+	}
+	_, err = collection.CreateItem(
+		libsecretLabelPrefix+ref,
+		map[string]string{"ref": ref},
+		data,
+		true,
+	)
+	if err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Set(ref, creds)
+	}
+	return nil
+}
+
+func (s *libsecretCredentialStore) Delete(ref string) error {
+	collection, err := s.collection()
+	if err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Delete(ref)
+	}
+	items, err := collection.SearchItems(map[string]string{"ref": ref})
+	if err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Delete(ref)
+	}
+	for _, item := range items {
+		if err = item.Delete(); err != nil {
+			if s.fallback == nil {
+				return err
+			}
+			return s.fallback.Delete(ref)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+// +build !darwin,!windows,!linux
+
+package config
+
+import "github.com/Sirupsen/logrus"
+
+// NewCredentialStore returns the encrypted-file CredentialStore on
+// platforms with no native secret store support in this CLI.
+func NewCredentialStore() CredentialStore {
+	fallback, err := newFileCredentialStore()
+	if err != nil {
+		logrus.Fatalf("Error preparing credential store: %s", err.Error())
+	}
+	return fallback
+}
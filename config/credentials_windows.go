@@ -0,0 +1,71 @@
+package config
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/danieljoos/wincred"
+)
+
+const wincredPrefix = "datica:"
+
+type wincredCredentialStore struct {
+	fallback *fileCredentialStore
+}
+
+// NewCredentialStore returns a CredentialStore backed by the Windows
+// Credential Manager, falling back to an encrypted file if it cannot be
+// reached.
+func NewCredentialStore() CredentialStore {
+	fallback, err := newFileCredentialStore()
+	if err != nil {
+		logrus.Debugf("Error preparing fallback credential store: %s", err.Error())
+	}
+	return &wincredCredentialStore{fallback: fallback}
+}
+
+func (s *wincredCredentialStore) Get(ref string) (*Credentials, error) {
+	cred, err := wincred.GetGenericCredential(wincredPrefix + ref)
+	if err != nil {
+		if s.fallback == nil {
+			return nil, err
+		}
+		return s.fallback.Get(ref)
+	}
+	var creds Credentials
+	if err = unmarshalCredentials(cred.CredentialBlob, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (s *wincredCredentialStore) Set(ref string, creds *Credentials) error {
+	data, err := marshalCredentials(creds)
+	if err != nil {
+		return err
+	}
+	cred := wincred.NewGenericCredential(wincredPrefix + ref)
+	cred.CredentialBlob = data
+	if err = cred.Write(); err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Set(ref, creds)
+	}
+	return nil
+}
+
+func (s *wincredCredentialStore) Delete(ref string) error {
+	cred, err := wincred.GetGenericCredential(wincredPrefix + ref)
+	if err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Delete(ref)
+	}
+	if err = cred.Delete(); err != nil {
+		if s.fallback == nil {
+			return err
+		}
+		return s.fallback.Delete(ref)
+	}
+	return nil
+}
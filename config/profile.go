@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/models"
+)
+
+// ListProfiles returns the names of every profile in settings, with the
+// currently active profile name reported separately.
+func ListProfiles(settings *models.Settings) (names []string, current string) {
+	for name := range settings.Profiles {
+		names = append(names, name)
+	}
+	return names, settings.CurrentProfile
+}
+
+// AddProfile creates a new, empty profile with the given name and persists
+// it to disk. It returns an error if a profile with that name already
+// exists.
+func AddProfile(name string, settings *models.Settings) error {
+	if _, ok := settings.Profiles[name]; ok {
+		return fmt.Errorf("A profile named \"%s\" already exists. Run \"datica profile list\" to see existing profiles.", name)
+	}
+	if settings.Profiles == nil {
+		settings.Profiles = map[string]models.Profile{}
+	}
+	settings.Profiles[name] = models.Profile{
+		Environments: map[string]models.AssociatedEnv{},
+	}
+	SaveSettings(settings)
+	return nil
+}
+
+// UseProfile switches the active profile to name, persisting the choice so
+// future invocations default to it until overridden by --profile or
+// DATICA_PROFILE.
+func UseProfile(name string, settings *models.Settings) error {
+	if _, ok := settings.Profiles[name]; !ok {
+		return fmt.Errorf("No profile named \"%s\" exists. Run \"datica profile list\" to see existing profiles or \"datica profile add\" to create it.", name)
+	}
+	settings.CurrentProfile = name
+	SaveSettings(settings)
+	return nil
+}
+
+// RemoveProfile deletes the named profile. The currently active profile and
+// the "default" profile cannot be removed.
+func RemoveProfile(name string, settings *models.Settings) error {
+	if _, ok := settings.Profiles[name]; !ok {
+		return fmt.Errorf("No profile named \"%s\" exists. Run \"datica profile list\" to see existing profiles.", name)
+	}
+	if name == DefaultProfile {
+		return fmt.Errorf("The \"%s\" profile cannot be removed.", DefaultProfile)
+	}
+	if name == settings.CurrentProfile {
+		return fmt.Errorf("Cannot remove the currently active profile \"%s\". Run \"datica profile use\" to switch to another profile first.", name)
+	}
+	if ref := settings.Profiles[name].CredentialRef; ref != "" {
+		if err := credentialStore().Delete(ref); err != nil {
+			logrus.Debugf("Error removing stored credentials for profile \"%s\": %s", name, err.Error())
+		}
+	}
+	delete(settings.Profiles, name)
+	SaveSettings(settings)
+	return nil
+}
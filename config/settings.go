@@ -1,15 +1,16 @@
 package config
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/Sirupsen/logrus"
-	"github.com/daticahealth/cli/models"
+	"github.com/catalyzeio/cli/models"
+	"github.com/gofrs/flock"
 	"github.com/mitchellh/go-homedir"
 )
 
@@ -19,20 +20,76 @@ const (
 	SettingsFile    = ".datica"
 )
 
+const (
+	// DefaultProfile is the name of the profile that the contents of a
+	// pre-profile settings file are migrated into.
+	DefaultProfile = "default"
+	// ProfileEnvVar is the environment variable used to select a profile
+	// when the --profile flag is not given.
+	ProfileEnvVar = "DATICA_PROFILE"
+	// SettingsFileEnvVar overrides the settings filename used in place of
+	// SettingsFile, so its extension can be changed to exercise the YAML
+	// codec instead of the default extensionless (JSON) file, e.g.
+	// DATICA_SETTINGS_FILE=settings.yaml.
+	SettingsFileEnvVar = "DATICA_SETTINGS_FILE"
+)
+
+// CurrentSchemaVersion is the settings file format version written by this
+// build of the CLI. GetSettings runs in-place migrations for any settings
+// file found with an older version.
+//
+// Version 2 moved Username, Password, and SessionToken out of the settings
+// file and into a CredentialStore; migrateCredentials performs that move
+// the first time a version 1 (or older) settings file is loaded.
+const CurrentSchemaVersion = 2
+
+var (
+	sharedCredentialStoreOnce sync.Once
+	sharedCredentialStore     CredentialStore
+)
+
+// credentialStore returns the CredentialStore for this process, constructing
+// it only the first time it is called. NewCredentialStore's encrypted-file
+// fallback prompts for a passphrase interactively when DATICA_PASSPHRASE is
+// unset, and GetSettings/SaveSettings can each run more than once in a
+// single command (e.g. a migration persisting its own result, then a
+// shutdown hook saving again); reusing one instance keeps that prompt to at
+// most once per invocation instead of once per call.
+func credentialStore() CredentialStore {
+	sharedCredentialStoreOnce.Do(func() {
+		sharedCredentialStore = NewCredentialStore()
+	})
+	return sharedCredentialStore
+}
+
+// settingsFileName returns the settings filename to use, honoring
+// SettingsFileEnvVar so its extension can select the YAML codec instead of
+// the default extensionless (JSON) file.
+func settingsFileName() string {
+	if name := os.Getenv(SettingsFileEnvVar); name != "" {
+		return name
+	}
+	return SettingsFile
+}
+
 // SettingsRetriever defines an interface for a class responsible for generating
 // a settings object used for most commands in the CLI. Some examples might be
 // for retrieving settings based on the settings file or generating a settings
 // object based on a directly entered environment ID and service ID.
 type SettingsRetriever interface {
-	GetSettings(string, string, string, string, string, string, string, string, string) *models.Settings
+	GetSettings(string, string, string, string, string, string, string, string, string, string) *models.Settings
 }
 
 // FileSettingsRetriever reads in data from the SettingsFile and generates a
 // settings object.
 type FileSettingsRetriever struct{}
 
-// GetSettings returns a Settings object for the current context
-func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authHost, ignoreAuthHostVersion, paasHost, ignorePaasHostVersion, username, password string) *models.Settings {
+// GetSettings returns a Settings object for the current context. profileName
+// selects which named profile's hosts, credentials, and environments to load
+// and takes precedence over the DATICA_PROFILE env var, which in turn takes
+// precedence over the active profile saved by "datica profile use"; the
+// "default" profile is used when none of these is set.
+func (s FileSettingsRetriever) GetSettings(profileName, envName, svcName, accountsHost, authHost, ignoreAuthHostVersion, paasHost, ignorePaasHostVersion, username, password string) *models.Settings {
 	HomeDir, err := homedir.Dir()
 	if err != nil {
 		logrus.Println(err.Error())
@@ -48,21 +105,46 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 		}
 	}
 
-	file, err := os.Open(filepath.Join(HomeDir, SettingsFile))
-	if os.IsNotExist(err) {
-		file, err = os.Create(filepath.Join(HomeDir, SettingsFile))
-	}
-	defer file.Close()
-	if err != nil {
+	settingsPath := filepath.Join(HomeDir, settingsFileName())
+	data, err := ioutil.ReadFile(settingsPath)
+	if err != nil && !os.IsNotExist(err) {
 		logrus.Println(err.Error())
 		os.Exit(1)
 	}
 	var settings models.Settings
-	json.NewDecoder(file).Decode(&settings)
-	if settings.Environments == nil {
-		settings.Environments = make(map[string]models.AssociatedEnv)
+	if len(data) > 0 {
+		if err = unmarshalSettings(settingsPath, data, &settings); err != nil {
+			logrus.Printf("Error parsing %s: %s", settingsPath, err.Error())
+			os.Exit(1)
+		}
+	}
+	profilesMigrated := migrateToProfiles(&settings)
+	store := credentialStore()
+	credentialsMigrated := migrateCredentials(&settings, store)
+	schemaMigrated := migrateSchema(&settings)
+	if profilesMigrated || credentialsMigrated || schemaMigrated {
+		SaveSettings(&settings)
 	}
 
+	if profileName == "" {
+		profileName = os.Getenv(ProfileEnvVar)
+	}
+	if profileName == "" {
+		profileName = settings.CurrentProfile
+	}
+	if profileName == "" {
+		profileName = DefaultProfile
+	}
+	profile, ok := settings.Profiles[profileName]
+	if !ok {
+		logrus.Fatalf("No profile named \"%s\" exists. Run \"datica profile list\" to see available profiles or \"datica profile add\" to create a new one", profileName)
+	}
+	settings.CurrentProfile = profileName
+	if profile.Environments == nil {
+		profile.Environments = make(map[string]models.AssociatedEnv)
+	}
+	settings.Environments = profile.Environments
+
 	// try and set the given env first, if it exists
 	if envName != "" {
 		setGivenEnv(envName, &settings)
@@ -73,7 +155,32 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 
 	// if not given, try default. this is deprecated and will be removed soon
 	if settings.EnvironmentID == "" || settings.ServiceID == "" {
-		setGivenEnv(settings.Default, &settings)
+		setGivenEnv(profile.Default, &settings)
+	}
+
+	if accountsHost == "" {
+		accountsHost = profile.AccountsHost
+	}
+	if authHost == "" {
+		authHost = profile.AuthHost
+	}
+	if paasHost == "" {
+		paasHost = profile.PaasHost
+	}
+
+	ref := profile.CredentialRef
+	if ref == "" {
+		ref = credentialRef(profileName)
+	}
+	creds, err := store.Get(ref)
+	if err != nil && err != ErrCredentialNotFound {
+		logrus.Debugf("Error reading stored credentials for %s: %s", ref, err.Error())
+	}
+	if creds != nil {
+		if username == "" {
+			username = creds.Username
+		}
+		settings.SessionToken = creds.SessionToken
 	}
 
 	settings.AccountsHost = accountsHost
@@ -109,19 +216,60 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 	return &settings
 }
 
-// SaveSettings persists the settings to disk
+// SaveSettings persists the settings to disk. The write is made atomic by
+// writing to a temp file in the same directory and renaming it into place,
+// which is atomic on POSIX filesystems, under an advisory file lock so two
+// concurrent CLI invocations cannot interleave writes and corrupt the file.
 func SaveSettings(settings *models.Settings) {
 	HomeDir, err := homedir.Dir()
 	if err != nil {
 		logrus.Println(err.Error())
 		os.Exit(1)
 	}
-	b, _ := json.Marshal(&settings)
-	err = ioutil.WriteFile(filepath.Join(HomeDir, SettingsFile), b, 0644)
+	settingsPath := filepath.Join(HomeDir, settingsFileName())
+
+	lock := flock.New(settingsPath + ".lock")
+	if err = lock.Lock(); err != nil {
+		logrus.Println(err.Error())
+		os.Exit(1)
+	}
+	defer lock.Unlock()
+
+	settings.SchemaVersion = CurrentSchemaVersion
+	saveCredentials(settings)
+	b, err := marshalSettings(settingsPath, settings)
+	if err != nil {
+		logrus.Println(err.Error())
+		os.Exit(1)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(settingsPath), filepath.Base(settingsPath)+".tmp")
 	if err != nil {
 		logrus.Println(err.Error())
 		os.Exit(1)
 	}
+	tmpPath := tmp.Name()
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		logrus.Println(err.Error())
+		os.Exit(1)
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		logrus.Println(err.Error())
+		os.Exit(1)
+	}
+	if err = os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		logrus.Println(err.Error())
+		os.Exit(1)
+	}
+	if err = os.Rename(tmpPath, settingsPath); err != nil {
+		os.Remove(tmpPath)
+		logrus.Println(err.Error())
+		os.Exit(1)
+	}
 }
 
 // DeleteBreadcrumb removes the environment in the  global list
@@ -135,6 +283,115 @@ func DeleteBreadcrumb(alias string, settings *models.Settings) error {
 	return nil
 }
 
+// migrateToProfiles moves the top-level AccountsHost/AuthHost/PaasHost,
+// Username, Pod, and Environments fields of a pre-profile settings file into
+// a "default" profile, parallel to the .catalyze -> .datica file migration
+// above, then clears the top-level Environments so it isn't written twice.
+// It is a no-op once a settings file already has profiles, and reports
+// whether it changed settings so the caller knows to persist the result.
+func migrateToProfiles(settings *models.Settings) bool {
+	if settings.Profiles != nil {
+		return false
+	}
+	logrus.Debugln("Migrating settings file to support named profiles")
+	settings.Profiles = map[string]models.Profile{
+		DefaultProfile: {
+			AccountsHost:   settings.AccountsHost,
+			AuthHost:       settings.AuthHost,
+			PaasHost:       settings.PaasHost,
+			LegacyUsername: settings.LegacyUsername,
+			Pod:            settings.Pod,
+			Default:        settings.Default,
+			Environments:   settings.Environments,
+		},
+	}
+	settings.Environments = nil
+	return true
+}
+
+// migrateCredentials moves any plaintext Username/SessionToken left over
+// from a pre-CredentialStore settings file into store, keyed per profile,
+// then clears them so they are never written back to disk. It runs after
+// migrateToProfiles, which already moved a pre-profile file's top-level
+// Username into the default profile's LegacyUsername; the default profile's
+// LegacyUsername and the top-level LegacySessionToken are merged into a
+// single Credentials and written with one Set call, since Set fully
+// replaces whatever is already stored under ref. It reports whether it
+// changed settings so the caller knows to persist the result.
+func migrateCredentials(settings *models.Settings, store CredentialStore) bool {
+	changed := false
+	legacySessionToken := settings.LegacySessionToken
+	if legacySessionToken != "" || settings.LegacyUsername != "" {
+		settings.LegacySessionToken = ""
+		settings.LegacyUsername = ""
+		changed = true
+	}
+
+	for name, profile := range settings.Profiles {
+		creds := Credentials{Username: profile.LegacyUsername}
+		if name == DefaultProfile {
+			creds.SessionToken = legacySessionToken
+		}
+		if creds.Username == "" && creds.SessionToken == "" {
+			continue
+		}
+		ref := profile.CredentialRef
+		if ref == "" {
+			ref = credentialRef(name)
+		}
+		logrus.Debugf("Migrating plaintext credentials for profile \"%s\" into the OS credential store", name)
+		if err := store.Set(ref, &creds); err != nil {
+			logrus.Debugf("Error migrating credentials for profile \"%s\": %s", name, err.Error())
+			continue
+		}
+		profile.CredentialRef = ref
+		profile.LegacyUsername = ""
+		settings.Profiles[name] = profile
+		changed = true
+	}
+	return changed
+}
+
+// saveCredentials writes the current profile's Username and SessionToken to
+// the OS credential store and records the resulting CredentialRef on the
+// profile, so SaveSettings never writes the secrets themselves to the
+// settings file. Password is never persisted anywhere: GetSettings never
+// reads it back, so storing it would just be unused secret-bearing state.
+func saveCredentials(settings *models.Settings) {
+	profile, ok := settings.Profiles[settings.CurrentProfile]
+	if !ok {
+		return
+	}
+	ref := profile.CredentialRef
+	if ref == "" {
+		ref = credentialRef(settings.CurrentProfile)
+	}
+	if err := credentialStore().Set(ref, &Credentials{
+		Username:     settings.Username,
+		SessionToken: settings.SessionToken,
+	}); err != nil {
+		logrus.Printf("Error saving credentials: %s", err.Error())
+		return
+	}
+	profile.CredentialRef = ref
+	settings.Profiles[settings.CurrentProfile] = profile
+}
+
+// migrateSchema runs any migrations needed to bring an older settings file
+// up to CurrentSchemaVersion. It is additive: each version bump should add a
+// case here rather than rewriting previous ones, the same way the
+// .catalyze -> .datica file migration is kept around rather than assuming
+// every user has already run it. It reports whether it changed settings so
+// the caller knows to persist the result.
+func migrateSchema(settings *models.Settings) bool {
+	if settings.SchemaVersion >= CurrentSchemaVersion {
+		return false
+	}
+	logrus.Debugf("Migrating settings file from schema version %d to %d", settings.SchemaVersion, CurrentSchemaVersion)
+	settings.SchemaVersion = CurrentSchemaVersion
+	return true
+}
+
 // setGivenEnv takes the given env name and finds it in the env list
 // in the given settings object. It then populates the EnvironmentID and
 // ServiceID on the settings object with appropriate values.
@@ -0,0 +1,139 @@
+// Package plugins discovers and runs datica-<name> executables on $PATH as
+// subcommands of the CLI, the same way kubectl and juju's cmd package
+// support external plugins.
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/models"
+)
+
+// Prefix is the filename prefix that marks an executable on $PATH as a
+// datica plugin. An executable named "datica-db-snapshot" becomes the
+// subcommand "datica db-snapshot".
+const Prefix = "datica-"
+
+// RcPassthroughError indicates a plugin exited with a non-zero status. The
+// caller should exit with Code rather than logging Error() and exiting 1,
+// so a plugin's own exit code reaches the user unchanged.
+type RcPassthroughError struct {
+	Code int
+}
+
+func (e RcPassthroughError) Error() string {
+	return fmt.Sprintf("plugin exited with status %d", e.Code)
+}
+
+// Discover returns every datica-<name> executable found on $PATH, keyed by
+// the <name> the user would type after "datica".
+func Discover() map[string]string {
+	found := map[string]string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), Prefix) || entry.Mode()&0111 == 0 {
+				continue
+			}
+			name := strings.TrimPrefix(entry.Name(), Prefix)
+			if _, ok := found[name]; !ok {
+				found[name] = filepath.Join(dir, entry.Name())
+			}
+		}
+	}
+	return found
+}
+
+// Names returns the discovered plugin names in sorted order.
+func Names(found map[string]string) []string {
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run execs the plugin at path with args, inheriting stdio and injecting
+// the current settings as DATICA_* environment variables. If the plugin
+// exits with a non-zero status, Run returns an RcPassthroughError carrying
+// that status rather than a generic error.
+func Run(path string, args []string, settings *models.Settings) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), envFor(settings)...)
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return RcPassthroughError{Code: status.ExitStatus()}
+		}
+		return RcPassthroughError{Code: 1}
+	}
+	return err
+}
+
+// Dispatch looks up name among the plugins discovered on $PATH and, if one
+// matches, execs it with args via Run and returns true along with Run's
+// result. It returns false, nil if no plugin named name was found, so the
+// caller can fall through to its own "unknown command" handling.
+func Dispatch(name string, args []string, settings *models.Settings) (bool, error) {
+	found := Discover()
+	path, ok := found[name]
+	if !ok {
+		return false, nil
+	}
+	return true, Run(path, args, settings)
+}
+
+// HandleUnknownCommand is meant to be called from the top-level command
+// dispatcher before it gives up on an unrecognized "datica <name>"
+// invocation. If name resolves to a plugin, it runs it via Dispatch and
+// never returns: it os.Exits with the plugin's own exit code on an
+// RcPassthroughError, 0 on success, or 1 after logging any other error. If
+// no plugin named name was found, it returns so the caller can continue
+// with its own "unknown command" handling.
+func HandleUnknownCommand(name string, args []string, settings *models.Settings) {
+	handled, err := Dispatch(name, args, settings)
+	if !handled {
+		return
+	}
+	if err == nil {
+		os.Exit(0)
+	}
+	if rc, ok := err.(RcPassthroughError); ok {
+		os.Exit(rc.Code)
+	}
+	logrus.Fatal(err.Error())
+}
+
+// envFor translates the resolved settings into DATICA_* environment
+// variables a plugin can use without re-resolving hosts or re-signing in.
+func envFor(settings *models.Settings) []string {
+	return []string{
+		"DATICA_ACCOUNTS_HOST=" + settings.AccountsHost,
+		"DATICA_AUTH_HOST=" + settings.AuthHost,
+		"DATICA_PAAS_HOST=" + settings.PaasHost,
+		"DATICA_SESSION_TOKEN=" + settings.SessionToken,
+		"DATICA_ENVIRONMENT_ID=" + settings.EnvironmentID,
+		"DATICA_SERVICE_ID=" + settings.ServiceID,
+		"DATICA_ORG_ID=" + settings.OrgID,
+		"DATICA_POD=" + settings.Pod,
+	}
+}
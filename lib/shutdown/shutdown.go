@@ -0,0 +1,58 @@
+// Package shutdown provides shared signal handling for long-running CLI
+// commands (the streaming metrics sub commands, "metrics serve", and
+// "metrics push") so Ctrl-C aborts in-flight requests cleanly instead of
+// leaving dangling sessions on the paas host.
+package shutdown
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/catalyzeio/cli/config"
+	"github.com/catalyzeio/cli/lib/auth"
+	"github.com/catalyzeio/cli/lib/prompts"
+	"github.com/catalyzeio/cli/models"
+)
+
+// NotifyContext returns a context that is canceled on SIGINT or SIGTERM,
+// along with a cancel func the caller must call once it is done with the
+// context (including on ordinary, non-interrupted completion) so in-flight
+// HTTP calls threaded through ctx are aborted and the signal notification
+// is released.
+func NotifyContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// WatchAndCleanup starts a goroutine that waits for a SIGINT or SIGTERM.
+// It listens on its own signal channel rather than ctx.Done(), because
+// every caller also cancels ctx on ordinary command completion and that
+// must not trigger a session invalidation. If signedIn is false (Signin
+// reused an existing session rather than creating a new one this run),
+// or ctx is done first because the command finished on its own, the
+// goroutine exits without doing anything. Otherwise it makes a
+// best-effort attempt to invalidate the session it created and persists
+// the resulting settings, so the next invocation does not try to reuse a
+// now-dead token.
+func WatchAndCleanup(ctx context.Context, settings *models.Settings, signedIn bool) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(sigCh)
+		select {
+		case <-sigCh:
+		case <-ctx.Done():
+			return
+		}
+		if !signedIn {
+			return
+		}
+		logrus.Debugln("Shutting down, invalidating session")
+		if err := auth.New(settings, prompts.New()).Signout(); err != nil {
+			logrus.Debugf("Error invalidating session during shutdown: %s", err.Error())
+		}
+		config.SaveSettings(settings)
+	}()
+}
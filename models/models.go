@@ -0,0 +1,122 @@
+package models
+
+import "github.com/jawher/mow.cli"
+
+// Command is the contract between the user and the CLI for a single command
+// or subcommand. It specifies the name, help text, and a function that wires
+// up the command's arguments, options, and action against a *Settings
+// instance.
+type Command struct {
+	Name      string
+	ShortHelp string
+	LongHelp  string
+	CmdFunc   func(settings *Settings) func(cmd *cli.Cmd)
+}
+
+// AssociatedEnv holds the identifiers for a single environment/service
+// association created with "datica associate".
+type AssociatedEnv struct {
+	Name            string `json:"name" yaml:"name"`
+	EnvironmentID   string `json:"environmentID" yaml:"environmentID"`
+	ServiceID       string `json:"serviceID" yaml:"serviceID"`
+	Pod             string `json:"pod" yaml:"pod"`
+	OrgID           string `json:"orgID" yaml:"orgID"`
+	EnvironmentName string `json:"environmentName" yaml:"environmentName"`
+}
+
+// Profile groups together the host configuration and associated
+// environments that belong to a single named profile (e.g. "production",
+// "staging", "dev"). A Settings object always resolves to exactly one
+// active Profile before a command runs.
+type Profile struct {
+	AccountsHost string                   `json:"accountsHost" yaml:"accountsHost"`
+	AuthHost     string                   `json:"authHost" yaml:"authHost"`
+	PaasHost     string                   `json:"paasHost" yaml:"paasHost"`
+	Pod          string                   `json:"pod" yaml:"pod"`
+	Default      string                   `json:"default" yaml:"default"`
+	Environments map[string]AssociatedEnv `json:"environments" yaml:"environments"`
+
+	// CredentialRef is the key this profile's username and session token
+	// are stored under in the OS credential store (e.g. "datica/production").
+	// The password is not stored. The secrets themselves are never written
+	// to the settings file.
+	CredentialRef string `json:"credentialRef,omitempty" yaml:"credentialRef,omitempty"`
+
+	// LegacyUsername is only populated when decoding a settings file
+	// written before credentials moved into the OS credential store.
+	// config.migrateCredentials reads it once, stores it, and clears it.
+	LegacyUsername string `json:"username,omitempty" yaml:"username,omitempty"`
+}
+
+// Settings is the in-memory representation of the `.datica` settings file
+// plus any values resolved from flags, env vars, or the current association.
+// It is threaded through every command.
+type Settings struct {
+	// SchemaVersion is the settings file format version this struct was
+	// decoded from (or defaults to 0 for files written before schema
+	// versioning existed). config.GetSettings migrates it up to
+	// config.CurrentSchemaVersion in place.
+	SchemaVersion int `json:"schemaVersion" yaml:"schemaVersion"`
+
+	Profiles       map[string]Profile `json:"profiles" yaml:"profiles"`
+	CurrentProfile string             `json:"currentProfile" yaml:"currentProfile"`
+
+	// Default is retained at the top level for backwards compatibility with
+	// settings files written before named profiles existed. It is migrated
+	// into the "default" profile the first time GetSettings runs.
+	Default         string                   `json:"default,omitempty" yaml:"default,omitempty"`
+	Environments    map[string]AssociatedEnv `json:"environments,omitempty" yaml:"environments,omitempty"`
+	EnvironmentID   string                   `json:"-" yaml:"-"`
+	EnvironmentName string                   `json:"-" yaml:"-"`
+	ServiceID       string                   `json:"-" yaml:"-"`
+	OrgID           string                   `json:"-" yaml:"-"`
+	Pod             string                   `json:"-" yaml:"-"`
+
+	AccountsHost    string `json:"-" yaml:"-"`
+	AuthHost        string `json:"-" yaml:"-"`
+	AuthHostVersion string `json:"-" yaml:"-"`
+	PaasHost        string `json:"-" yaml:"-"`
+	PaasHostVersion string `json:"-" yaml:"-"`
+
+	Username     string `json:"-" yaml:"-"`
+	Password     string `json:"-" yaml:"-"`
+	SessionToken string `json:"-" yaml:"-"`
+	UsersID      string `json:"usersID,omitempty" yaml:"usersID,omitempty"`
+
+	// LegacyUsername and LegacySessionToken are only populated when
+	// decoding a pre-profile settings file that stored credentials as
+	// plaintext top-level fields. config.migrateCredentials reads them
+	// once, stores them, and clears them.
+	LegacyUsername     string `json:"username,omitempty" yaml:"username,omitempty"`
+	LegacySessionToken string `json:"sessionToken,omitempty" yaml:"sessionToken,omitempty"`
+
+	Version string `json:"-" yaml:"-"`
+}
+
+// Metrics holds a single service or environment metrics sample as returned
+// by the paas host.
+type Metrics struct {
+	Type            string  `json:"type"`
+	ServiceName     string  `json:"serviceName"`
+	EnvironmentName string  `json:"environmentName"`
+	Pod             string  `json:"pod"`
+	OrgID           string  `json:"orgID"`
+	Value           float64 `json:"value"`
+	Time            string  `json:"time"`
+}
+
+// Service describes a single service within an environment, as returned by
+// the `services list` command.
+type Service struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// StatusReport describes the health of the current environment, as returned
+// by the `status` command.
+type StatusReport struct {
+	EnvironmentName string `json:"environmentName"`
+	Healthy         bool   `json:"healthy"`
+	Message         string `json:"message"`
+}